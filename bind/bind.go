@@ -0,0 +1,18 @@
+// Package bind layers a reflection-based, idiomatic Go API over the
+// low-level lua.State primitives (Tuserdata, Upvalueindex and friends)
+// for embedders who would rather hand Lua a Go value than hand-write
+// cgo glue for it.
+//
+// Register exposes a Go struct as a Lua module backed by userdata with
+// a generated metatable; Func wraps an arbitrary Go function so it can
+// be called directly from Lua. Both use reflect to marshal values
+// across the stack, and pin every Go value they hand to Lua in a
+// package-level Go map keyed by a per-value handle (see pin.go) so it
+// survives Go's moving GC until Lua's own __gc releases it.
+package bind
+
+import "errors"
+
+// ErrUnsupportedType is returned when a Go value has no Lua-side
+// representation Func or Register knows how to marshal.
+var ErrUnsupportedType = errors.New("bind: unsupported type")
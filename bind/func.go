@@ -0,0 +1,215 @@
+package bind
+
+import (
+	"fmt"
+	"reflect"
+
+	lua "newblue/lua"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Func wraps fn, an arbitrary Go function, as a Lua C function and
+// pushes it onto the stack. Arguments are converted from Lua values to
+// fn's parameter types with reflect; if fn's last return value is a
+// non-nil error, it is raised with lua_error instead of being returned
+// to Lua.
+func Func(L *lua.State, fn interface{}) error {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return fmt.Errorf("bind: Func wants a function, got %T", fn)
+	}
+	L.Pushgofunction(wrapFunc(rv))
+	return nil
+}
+
+func wrapFunc(rv reflect.Value) lua.Gofunction {
+	t := rv.Type()
+	return func(L *lua.State) int {
+		args, err := pullArgs(L, t)
+		if err != nil {
+			return L.Error(err.Error())
+		}
+		return pushResults(L, t, rv.Call(args))
+	}
+}
+
+func wrapMethod(m reflect.Value) lua.Gofunction {
+	t := m.Type()
+	return func(L *lua.State) int {
+		// Argument 1 is the receiver userdata, already bound into m.
+		args, err := pullArgsFrom(L, t, 2)
+		if err != nil {
+			return L.Error(err.Error())
+		}
+		return pushResults(L, t, m.Call(args))
+	}
+}
+
+func pullArgs(L *lua.State, t reflect.Type) ([]reflect.Value, error) {
+	return pullArgsFrom(L, t, 1)
+}
+
+func pullArgsFrom(L *lua.State, t reflect.Type, first int) ([]reflect.Value, error) {
+	n := t.NumIn()
+	args := make([]reflect.Value, n)
+	for i := 0; i < n; i++ {
+		v, err := pullGoValue(L, first+i, t.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("bind: argument %d: %w", i+1, err)
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+func pushResults(L *lua.State, t reflect.Type, results []reflect.Value) int {
+	nout := t.NumOut()
+	if nout > 0 && t.Out(nout-1) == errorType {
+		if err, _ := results[nout-1].Interface().(error); err != nil {
+			return L.Error(err.Error())
+		}
+		results = results[:nout-1]
+	}
+	for _, r := range results {
+		pushGoValue(L, r)
+	}
+	return len(results)
+}
+
+// pushGoValue converts a reflect.Value to a Lua value on top of the
+// stack, returning the number of values pushed (always 1).
+func pushGoValue(L *lua.State, v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Bool:
+		L.Pushboolean(v.Bool())
+	case reflect.String:
+		L.Pushstring(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		L.Pushnumber(toFloat(v))
+	case reflect.Slice, reflect.Array:
+		L.Newtable()
+		for i := 0; i < v.Len(); i++ {
+			pushGoValue(L, v.Index(i))
+			L.Rawseti(-2, i+1)
+		}
+	case reflect.Map:
+		L.Newtable()
+		for _, k := range v.MapKeys() {
+			pushGoValue(L, k)
+			pushGoValue(L, v.MapIndex(k))
+			L.Rawset(-3)
+		}
+	case reflect.Ptr, reflect.Struct:
+		pushUserdata(L, addressable(v))
+	case reflect.Invalid:
+		L.Pushnil()
+	default:
+		L.Pushnil()
+	}
+	return 1
+}
+
+func setGoValue(L *lua.State, idx int, field reflect.Value) error {
+	v, err := pullGoValue(L, idx, field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(v)
+	return nil
+}
+
+// pullGoValue converts the Lua value at idx to a reflect.Value of type
+// want, the inverse of pushGoValue.
+func pullGoValue(L *lua.State, idx int, want reflect.Type) (reflect.Value, error) {
+	switch want.Kind() {
+	case reflect.Bool:
+		return reflect.ValueOf(L.Toboolean(idx)).Convert(want), nil
+	case reflect.String:
+		return reflect.ValueOf(L.Tostring(idx)).Convert(want), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return reflect.ValueOf(L.Tonumber(idx)).Convert(want), nil
+	case reflect.Slice:
+		return pullSlice(L, idx, want)
+	case reflect.Map:
+		return pullMap(L, idx, want)
+	case reflect.Ptr, reflect.Struct:
+		handle := userdataHandle(L, idx)
+		rv, ok := unpin(handle)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%s: use of a collected or foreign userdata", want)
+		}
+		if want.Kind() == reflect.Struct {
+			// pushGoValue pinned a pointer to a copy of the struct.
+			return rv.Elem(), nil
+		}
+		return rv, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedType, want)
+	}
+}
+
+func pullSlice(L *lua.State, idx int, want reflect.Type) (reflect.Value, error) {
+	n := L.Objlen(idx)
+	out := reflect.MakeSlice(want, n, n)
+	for i := 0; i < n; i++ {
+		L.Rawgeti(idx, i+1)
+		v, err := pullGoValue(L, -1, want.Elem())
+		L.Pop(1)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.Index(i).Set(v)
+	}
+	return out, nil
+}
+
+func pullMap(L *lua.State, idx int, want reflect.Type) (reflect.Value, error) {
+	out := reflect.MakeMap(want)
+	L.Pushnil()
+	for L.Next(idx) {
+		k, err := pullGoValue(L, -2, want.Key())
+		if err == nil {
+			var v reflect.Value
+			v, err = pullGoValue(L, -1, want.Elem())
+			if err == nil {
+				out.SetMapIndex(k, v)
+			}
+		}
+		L.Pop(1)
+		if err != nil {
+			L.Pop(1) // drop the key too before giving up iteration
+			return reflect.Value{}, err
+		}
+	}
+	return out, nil
+}
+
+// addressable returns v itself if it is already a pointer, or the
+// address of a copy of v otherwise, since pushUserdata always expects
+// a pointer to pin.
+func addressable(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	default:
+		return 0
+	}
+}
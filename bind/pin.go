@@ -0,0 +1,44 @@
+package bind
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// pins keeps every Go value currently referenced by a piece of Lua
+// userdata alive, keyed by the address of the lightweight handle stored
+// in the userdata block. Go's garbage collector is free to move or
+// collect values it otherwise has no visible reference to; Lua only
+// ever holds that address, so without this table a Go GC between two
+// Lua calls could collect the value out from under it.
+var (
+	pinMu sync.Mutex
+	pins  = map[unsafe.Pointer]reflect.Value{}
+)
+
+// pin records v and returns the handle Lua userdata should store.
+func pin(v reflect.Value) unsafe.Pointer {
+	handle := new(byte)
+	key := unsafe.Pointer(handle)
+	pinMu.Lock()
+	pins[key] = v
+	pinMu.Unlock()
+	return key
+}
+
+// unpin looks up the value previously pinned under key.
+func unpin(key unsafe.Pointer) (reflect.Value, bool) {
+	pinMu.Lock()
+	v, ok := pins[key]
+	pinMu.Unlock()
+	return v, ok
+}
+
+// release drops the pin installed for key; called from the generated
+// __gc metamethod once Lua has collected the userdata.
+func release(key unsafe.Pointer) {
+	pinMu.Lock()
+	delete(pins, key)
+	pinMu.Unlock()
+}
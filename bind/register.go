@@ -0,0 +1,179 @@
+package bind
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	lua "newblue/lua"
+)
+
+// metaName returns the registry key used for the metatable generated
+// for t, so repeated Register/Func calls for the same Go type share one
+// metatable instead of allocating a fresh one per call.
+func metaName(t reflect.Type) string {
+	return "bind." + t.PkgPath() + "." + t.Name()
+}
+
+// Register exposes v, a pointer to a struct, as Lua userdata stored in
+// the field "instance" of the global table name (creating that table
+// if absent), so Lua reaches it as name.instance, with its exported
+// methods and fields reachable through a generated __index/__newindex
+// pair. Unexported fields and methods are invisible to Lua, the same as
+// they are to other Go packages.
+func Register(L *lua.State, name string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: Register wants a pointer to a struct, got %T", v)
+	}
+
+	pushUserdata(L, rv)
+
+	L.Getglobal(name)
+	if L.Type(-1) != lua.Ttable {
+		L.Pop(1)
+		L.Newtable()
+		L.Pushvalue(-1)
+		L.Setglobal(name)
+	}
+	L.Insert(-2)
+	L.Setfield(-2, "instance")
+	L.Pop(1)
+	return nil
+}
+
+// pushUserdata pushes a new full userdata wrapping rv (a pointer to a
+// struct) and sets its metatable, generating the metatable for rv's
+// type the first time it is needed.
+func pushUserdata(L *lua.State, rv reflect.Value) {
+	t := rv.Type()
+	name := metaName(t.Elem())
+
+	ud := L.Newuserdata(unsafe.Sizeof(uintptr(0)))
+	key := pin(rv)
+	*(*unsafe.Pointer)(ud) = key
+
+	if L.Newmetatable(name) {
+		buildMetatable(L, t.Elem())
+	}
+	L.Setmetatable(-2)
+}
+
+// buildMetatable fills in the metatable at the top of the stack for
+// struct type t with __index, __newindex, __gc, __tostring, __eq, and
+// a "methods" subtable, generated once per Go type and shared by every
+// instance of it.
+func buildMetatable(L *lua.State, t reflect.Type) {
+	// methods caches the Gofunction closure wrapping each method name
+	// indexStruct has already resolved, so repeated obj:Method() calls
+	// reuse the one closure registered the first time instead of
+	// registering (and leaking) a fresh one on every lookup.
+	L.Newtable()
+	L.Setfield(-2, "methods")
+
+	L.Pushgofunction(func(L *lua.State) int { return indexStruct(L, t) })
+	L.Setfield(-2, "__index")
+
+	L.Pushgofunction(func(L *lua.State) int { return newindexStruct(L, t) })
+	L.Setfield(-2, "__newindex")
+
+	L.Pushgofunction(gcUserdata)
+	L.Setfield(-2, "__gc")
+
+	L.Pushgofunction(func(L *lua.State) int { return tostringStruct(L, t) })
+	L.Setfield(-2, "__tostring")
+
+	L.Pushgofunction(eqUserdata)
+	L.Setfield(-2, "__eq")
+}
+
+func userdataHandle(L *lua.State, idx int) unsafe.Pointer {
+	return *(*unsafe.Pointer)(L.Touserdata(idx))
+}
+
+func indexStruct(L *lua.State, t reflect.Type) int {
+	handle := userdataHandle(L, 1)
+	rv, ok := unpin(handle)
+	if !ok {
+		return L.Error("bind: use of a collected or foreign userdata")
+	}
+	field := L.Tostring(2)
+
+	elem := rv.Elem()
+	if f := elem.FieldByName(field); f.IsValid() {
+		if sf, ok := t.FieldByName(field); ok && sf.IsExported() {
+			return pushGoValue(L, f)
+		}
+	}
+	if m := rv.MethodByName(field); m.IsValid() {
+		// Reuse the closure cached in this type's metatable from a
+		// previous lookup, if any, instead of registering a new
+		// Gofunction (and leaking the old one) on every access.
+		L.Newmetatable(metaName(t))
+		L.Getfield(-1, "methods")
+		L.Getfield(-1, field)
+		if L.Type(-1) == lua.Tfunction {
+			return 1
+		}
+		L.Pop(1) // drop the cache miss
+
+		// The cached closure is shared by every instance of t, so it
+		// binds the receiver from argument 1 on each call rather than
+		// keeping m, which is bound to this lookup's instance.
+		name := field
+		L.Pushgofunction(func(L *lua.State) int {
+			recv, ok := unpin(userdataHandle(L, 1))
+			if !ok {
+				return L.Error("bind: use of a collected or foreign userdata")
+			}
+			return wrapMethod(recv.MethodByName(name))(L)
+		})
+		L.Pushvalue(-1)
+		L.Setfield(-3, field)
+		return 1
+	}
+	L.Pushnil()
+	return 1
+}
+
+func newindexStruct(L *lua.State, t reflect.Type) int {
+	handle := userdataHandle(L, 1)
+	rv, ok := unpin(handle)
+	if !ok {
+		return L.Error("bind: use of a collected or foreign userdata")
+	}
+	field := L.Tostring(2)
+	elem := rv.Elem()
+	f := elem.FieldByName(field)
+	if !f.IsValid() || !f.CanSet() {
+		return L.Error(fmt.Sprintf("bind: %s has no settable field %q", t, field))
+	}
+	if err := setGoValue(L, 3, f); err != nil {
+		return L.Error(err.Error())
+	}
+	return 0
+}
+
+func tostringStruct(L *lua.State, t reflect.Type) int {
+	handle := userdataHandle(L, 1)
+	rv, ok := unpin(handle)
+	if !ok {
+		L.Pushstring(fmt.Sprintf("%s: <collected>", t))
+		return 1
+	}
+	L.Pushstring(fmt.Sprintf("%s: %p", t, rv.Interface()))
+	return 1
+}
+
+func gcUserdata(L *lua.State) int {
+	release(userdataHandle(L, 1))
+	return 0
+}
+
+func eqUserdata(L *lua.State) int {
+	a, okA := unpin(userdataHandle(L, 1))
+	b, okB := unpin(userdataHandle(L, 2))
+	L.Pushboolean(okA && okB && a.Interface() == b.Interface())
+	return 1
+}
+
@@ -0,0 +1,66 @@
+package bind
+
+import (
+	"testing"
+
+	lua "newblue/lua"
+)
+
+type bindInner struct {
+	Name string
+}
+
+type bindWidget struct {
+	bindInner
+	Count int
+}
+
+func (w *bindWidget) Bump() int {
+	w.Count++
+	return w.Count
+}
+
+func TestIndexStructPromotedField(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	w := &bindWidget{bindInner: bindInner{Name: "gizmo"}}
+	if err := Register(L, "widget", w); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if err := L.LoadSafe([]byte("return widget.instance.Name"), "promoted-field", "t"); err != nil {
+		t.Fatalf("LoadSafe: %v", err)
+	}
+	if err := L.Pcall(0, 1); err != nil {
+		t.Fatalf("Pcall: %v", err)
+	}
+	if got := L.Tostring(-1); got != "gizmo" {
+		t.Fatalf("got %q, want %q", got, "gizmo")
+	}
+}
+
+func TestIndexStructCachesMethodClosures(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	w := &bindWidget{}
+	if err := Register(L, "widget", w); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	src := `
+		local a = widget.instance.Bump
+		local b = widget.instance.Bump
+		return a == b
+	`
+	if err := L.LoadSafe([]byte(src), "method-cache", "t"); err != nil {
+		t.Fatalf("LoadSafe: %v", err)
+	}
+	if err := L.Pcall(0, 1); err != nil {
+		t.Fatalf("Pcall: %v", err)
+	}
+	if !L.Toboolean(-1) {
+		t.Fatal("expected repeated obj.Method lookups to return the same cached closure, not a fresh registration each time")
+	}
+}
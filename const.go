@@ -1,19 +1,20 @@
-// Package lua provides an interface to the Lua 5.1 interpreter.
+// Package lua provides an interface to the Lua interpreter.
+//
+// By default the package builds against Lua 5.1. Building with the
+// "lua54" tag switches the cgo headers and the pseudo-index/GC surface
+// to Lua 5.4 instead; see const_51.go and const_54.go for the parts
+// that differ between the two runtimes.
 package lua
 
 /*
+#cgo !lua54 pkg-config: lua5.1
+#cgo lua54 pkg-config: lua5.4
 #include <lua.h>
 #include <lualib.h>
 */
 import "C"
 import "errors"
 
-const (
-	Version    = C.LUA_VERSION
-	Versionnum = C.LUA_VERSION_NUM
-	Copyright  = C.LUA_COPYRIGHT
-)
-
 const (
 	Signature = C.LUA_SIGNATURE // mark for precompiled code (`<esc>Lua')
 	Multret   = C.LUA_MULTRET   // option for multiple returns in 'call' functions
@@ -30,7 +31,10 @@ const (
 	Errerr    = C.LUA_ERRERR
 )
 
-var errs map[int]error = map[int]error{
+// errs is populated with the error codes common to every supported
+// version; version-specific codes (e.g. Errfile under lua54) are added
+// to it from an init() in the matching const_5x.go.
+var errs = map[int]error{
 	Errrun:    errors.New("run time error"),
 	Errsyntax: errors.New("syntax error"),
 	Errmem:    errors.New("out of memory"),
@@ -47,36 +51,6 @@ func numtoerror(errnum int) error {
 	return errors.New("unknown error")
 }
 
-// Pseudo-indices. Unless otherwise noted, any function that accepts valid
-// indices can also be called with these pseudo-indices, which represent
-// some Lua values that are accessible to Go code but which are not in
-// the stack. Pseudo-indices are used to access the thread environment,
-// the function environment, the registry, and the upvalues of a Go function.
-//
-// The thread environment (where global variables live) is always at
-// pseudo-index Globalsindex. The environment of the running Go function
-// is always at pseudo-index Environindex.
-//
-// To access and change the value of global variables, you can use regular
-// table operations over an environment table. For instance, to access the
-// value of a global variable, do:
-//	s.Getfield(luajit.Globalsindex, varname);
-const (
-	Registryindex = C.LUA_REGISTRYINDEX
-	Environindex  = C.LUA_ENVIRONINDEX // env of running Go function
-	Globalsindex  = C.LUA_GLOBALSINDEX // thread env, where globals live
-)
-
-// Returns the pseudo-index for the nth upvalue of a Go closure.
-//
-// Whenever a Go closure is called from Lua, its upvalues are located
-// at specific pseudo-indices. These pseudo-indices are located using
-// Upvalueindex. The first value associated with a function is at position
-// Upvalueindex(1), and so on.
-func Upvalueindex(n int) int {
-	return (Globalsindex - n) + 1 // Upvalueindex(1) is reserved for Go func pointer
-}
-
 // Basic types
 const (
 	Tnone          = C.LUA_TNONE
@@ -91,7 +65,9 @@ const (
 	Tthread        = C.LUA_TTHREAD
 )
 
-// Garbage-collection function and options
+// Garbage-collection function and options common to every version.
+// The pause/step-multiplier and generational/incremental mode switches
+// differ between 5.1 and 5.4 and live in const_51.go / const_54.go.
 const (
 	// Stops the garbage collector.
 	GCstop = C.LUA_GCSTOP
@@ -110,15 +86,9 @@ const (
 	// tune the value of data. The function returns 1 if the step finished a
 	// garbage-collection cycle.
 	GCstep = C.LUA_GCSTEP
-	// Sets data as the new value for the pause of the collector. The function
-	// returns the previous value of the pause.
-	GCsetpause = C.LUA_GCSETPAUSE
-	// Sets data as the new value for the step multiplier of the collector. The
-	// function returns the previous value of the step multiplier.
-	GCsetstepmul = C.LUA_GCSETSTEPMUL
 )
 
-// Debug event codes
+// Debug event codes common to every version.
 const (
 	// The call hook is called when the interpreter calls a function. The
 	// hook is called just after Lua enters the new function, before
@@ -137,8 +107,7 @@ const (
 	// The count hook is called after the interpreter executes every
 	// count instructions. (This event only happens while Lua is
 	// executing a Lua function.)
-	Hookcount   = C.LUA_HOOKCOUNT
-	Hooktailret = C.LUA_HOOKTAILRET
+	Hookcount = C.LUA_HOOKCOUNT
 )
 
 // Debug event masks
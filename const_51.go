@@ -0,0 +1,56 @@
+//go:build !lua54
+
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+
+const (
+	Version    = C.LUA_VERSION
+	Versionnum = C.LUA_VERSION_NUM
+	Copyright  = C.LUA_COPYRIGHT
+)
+
+// Sets data as the new value for the pause of the collector. The function
+// returns the previous value of the pause.
+const GCsetpause = C.LUA_GCSETPAUSE
+
+// Sets data as the new value for the step multiplier of the collector. The
+// function returns the previous value of the step multiplier.
+const GCsetstepmul = C.LUA_GCSETSTEPMUL
+
+// The tail-return hook stands in for a proper tail-call event under 5.1;
+// see Hooktailcall in const_54.go for the 5.4 replacement.
+const Hooktailret = C.LUA_HOOKTAILRET
+
+// Pseudo-indices. Unless otherwise noted, any function that accepts valid
+// indices can also be called with these pseudo-indices, which represent
+// some Lua values that are accessible to Go code but which are not in
+// the stack. Pseudo-indices are used to access the thread environment,
+// the function environment, the registry, and the upvalues of a Go function.
+//
+// The thread environment (where global variables live) is always at
+// pseudo-index Globalsindex. The environment of the running Go function
+// is always at pseudo-index Environindex.
+//
+// To access and change the value of global variables, you can use regular
+// table operations over an environment table. For instance, to access the
+// value of a global variable, do:
+//	s.Getfield(luajit.Globalsindex, varname);
+const (
+	Registryindex = C.LUA_REGISTRYINDEX
+	Environindex  = C.LUA_ENVIRONINDEX // env of running Go function
+	Globalsindex  = C.LUA_GLOBALSINDEX // thread env, where globals live
+)
+
+// Returns the pseudo-index for the nth upvalue of a Go closure.
+//
+// Whenever a Go closure is called from Lua, its upvalues are located
+// at specific pseudo-indices. These pseudo-indices are located using
+// Upvalueindex. The first value associated with a function is at position
+// Upvalueindex(1), and so on.
+func Upvalueindex(n int) int {
+	return Globalsindex - n // Upvalueindex(1) holds Pushgofunction's func id
+}
@@ -0,0 +1,60 @@
+//go:build lua54
+
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+import "errors"
+
+const (
+	Version    = C.LUA_VERSION
+	Versionnum = C.LUA_VERSION_NUM
+	Copyright  = C.LUA_COPYRIGHT
+)
+
+// Errfile is returned by the loaders when a file cannot be opened or read.
+const Errfile = C.LUA_ERRFILE
+
+func init() {
+	errs[Errfile] = errors.New("cannot open/read file")
+}
+
+// Collector mode switches, in place of 5.1's GCsetpause/GCsetstepmul.
+// GCgen puts the collector in generational mode; data is the pair
+// (minormul, majormul), the minor and major collection multipliers.
+// GCinc puts the collector back in incremental mode; data is the triple
+// (pause, stepmul, stepsize) passed as three successive data ints.
+const (
+	GCgen = C.LUA_GCGEN
+	GCinc = C.LUA_GCINC
+)
+
+// Hooktailcall replaces 5.1's Hooktailret: it is called instead of a
+// return hook for functions that are called through a tail call.
+const Hooktailcall = C.LUA_HOOKTAILCALL
+
+// Pseudo-indices. 5.4 drops Globalsindex and Environindex (globals now
+// live in an upvalue of the running chunk, not a pseudo-index) and
+// computes Registryindex directly from LUAI_MAXSTACK instead of from
+// Globalsindex.
+const Registryindex = C.LUA_REGISTRYINDEX
+
+// Returns the pseudo-index for the nth upvalue of a Go closure.
+//
+// Whenever a Go closure is called from Lua, its upvalues are located
+// at specific pseudo-indices. These pseudo-indices are located using
+// Upvalueindex. The first value associated with a function is at position
+// Upvalueindex(1), and so on. Under 5.4 these are offsets from
+// Registryindex rather than from Globalsindex.
+func Upvalueindex(n int) int {
+	return Registryindex - n
+}
+
+// Absindex converts the acceptable index i into an equivalent absolute
+// index, one that does not depend on the size of the stack. It wraps
+// lua_absindex, which 5.1 has no equivalent for.
+func (s *State) Absindex(i int) int {
+	return int(C.lua_absindex(s.l, C.int(i)))
+}
@@ -0,0 +1,90 @@
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+import "fmt"
+
+// Coroutine wraps a Lua thread created with lua_newthread, so Go code
+// can drive it with Resume/Yield instead of manipulating the thread's
+// own stack directly.
+type Coroutine struct {
+	s *State
+}
+
+// NewCoroutine creates a new Lua thread on top of s (pushing it, as
+// lua_newthread does) and wraps it as a Coroutine. The coroutine shares
+// s's vm lock, since it is still the same global Lua state underneath
+// and the C API is not safe to enter for two threads of one global
+// state at once, even from different goroutines.
+func NewCoroutine(s *State) *Coroutine {
+	l := C.lua_newthread(s.l)
+	return &Coroutine{s: &State{l: l, vm: s.vm}}
+}
+
+// resumeResults collects the values left above base on the coroutine's
+// stack after a lua_resume call, the part of Resume that is the same
+// regardless of which version's resume signature produced them.
+func (c *Coroutine) resumeResults(base, status int) ([]interface{}, int, error) {
+	if status != Ok && status != Yield {
+		msg := c.s.Tostring(-1)
+		c.s.Pop(1)
+		return nil, status, fmt.Errorf("%s: %s", numtoerror(status), msg)
+	}
+	results := make([]interface{}, 0, c.s.Gettop()-base)
+	for i := base + 1; i <= c.s.Gettop(); i++ {
+		results = append(results, c.s.ToValue(i))
+	}
+	c.s.SetTop(base)
+	return results, status, nil
+}
+
+// Yield suspends the coroutine, passing args back to whoever resumed
+// it. It must be called from within a Go function that is itself
+// running as a callback on this coroutine's thread, which means the
+// vm lock Resume took to get here is already held by this goroutine;
+// Yield reuses it rather than taking it again, which would deadlock.
+func (c *Coroutine) Yield(args ...interface{}) int {
+	for _, a := range args {
+		c.s.Push(a)
+	}
+	return int(C.lua_yield(c.s.l, C.int(len(args))))
+}
+
+// Status reports the coroutine's current status: Ok once it has run to
+// completion, Yield while suspended, or one of the Err* codes if it
+// ended with an error.
+func (c *Coroutine) Status() int {
+	c.s.vm.Lock()
+	defer c.s.vm.Unlock()
+	return int(C.lua_status(c.s.l))
+}
+
+// Chan bridges the coroutine to a Go channel for pull-style iteration:
+// each resume that yields sends its tuple on the channel, and the
+// channel is closed once the coroutine returns or errors. The
+// background goroutine only ever calls Resume, which takes the shared
+// vm lock, so it serializes correctly against any other goroutine
+// using this coroutine's State or a sibling coroutine of the same
+// global state through the methods in this package; it is not safe
+// against code that reaches past those methods to c.s.l directly.
+func (c *Coroutine) Chan(args ...interface{}) <-chan []interface{} {
+	ch := make(chan []interface{})
+	go func() {
+		defer close(ch)
+		next := args
+		for {
+			results, status, err := c.Resume(next...)
+			if err != nil {
+				return
+			}
+			ch <- results
+			if status == Ok {
+				return
+			}
+			next = nil
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,36 @@
+//go:build !lua54
+
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+
+// Resume starts or continues the coroutine, passing args as the
+// function's arguments on the first call and as the results of the
+// pending Yield on every call after. It reports the values the
+// coroutine yielded or returned, its resulting Status, and an error if
+// it ended in one of the Err* codes.
+//
+// Resume takes the coroutine's vm lock for its duration, so it is safe
+// to call concurrently with other Resume/Yield/Status calls against
+// the same underlying global state (including from other coroutines
+// created from it, or via Chan) — they simply serialize.
+//
+// 5.1's lua_resume takes only the thread and the argument count; 5.4
+// added an explicit "from" thread and an out-pointer for the result
+// count (see coroutine_54.go).
+func (c *Coroutine) Resume(args ...interface{}) ([]interface{}, int, error) {
+	c.s.vm.Lock()
+	defer c.s.vm.Unlock()
+
+	base := c.s.Gettop()
+	for _, a := range args {
+		if err := c.s.Push(a); err != nil {
+			return nil, Errrun, err
+		}
+	}
+	status := int(C.lua_resume(c.s.l, C.int(len(args))))
+	return c.resumeResults(base, status)
+}
@@ -0,0 +1,106 @@
+//go:build lua54
+
+package lua
+
+/*
+#include <lua.h>
+
+extern int goContinuation(lua_State *L, int status, lua_KContext ctx);
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// Continuation is a Go-side continuation function: when a Lua call made
+// with Callk or Pcallk yields instead of returning, it is invoked on
+// resume in place of the Go frame that made the call, which has
+// already unwound across the C-call boundary the yield crossed.
+type Continuation func(s *State, status int) int
+
+// contMu guards continuations and lastContext: goContinuation can fire
+// on whatever OS thread the Go runtime schedules the resuming call on,
+// concurrently with another goroutine registering a new continuation
+// via Callk/Pcallk, so plain map/counter access isn't safe here (same
+// pattern as allocMu in sandbox.go and warnMu in warn.go).
+var (
+	contMu        sync.Mutex
+	continuations = map[uintptr]Continuation{}
+	lastContext   uintptr
+)
+
+func nextContext() uintptr {
+	contMu.Lock()
+	defer contMu.Unlock()
+	lastContext++
+	return lastContext
+}
+
+// Callk is lua_callk: like Call, but registers k to be invoked instead
+// of returning normally if the callee yields across this call. It
+// takes s's vm lock, like Pcall and Resume.
+func (s *State) Callk(nargs, nresults int, k Continuation) {
+	s.vm.Lock()
+	defer s.vm.Unlock()
+
+	ctx := nextContext()
+	contMu.Lock()
+	continuations[ctx] = k
+	contMu.Unlock()
+	C.lua_callk(s.l, C.int(nargs), C.int(nresults), C.lua_KContext(ctx), (C.lua_KFunction)(unsafe.Pointer(C.goContinuation)))
+}
+
+// Pcallk is lua_pcallk, the protected-call counterpart to Callk.
+func (s *State) Pcallk(nargs, nresults, errfunc int, k Continuation) int {
+	s.vm.Lock()
+	defer s.vm.Unlock()
+
+	ctx := nextContext()
+	contMu.Lock()
+	continuations[ctx] = k
+	contMu.Unlock()
+	return int(C.lua_pcallk(s.l, C.int(nargs), C.int(nresults), C.int(errfunc), C.lua_KContext(ctx), (C.lua_KFunction)(unsafe.Pointer(C.goContinuation))))
+}
+
+// Resume starts or continues the coroutine, passing args as the
+// function's arguments on the first call and as the results of the
+// pending Yield on every call after. It reports the values the
+// coroutine yielded or returned, its resulting Status, and an error if
+// it ended in one of the Err* codes.
+//
+// Resume takes the coroutine's vm lock for its duration, so it is safe
+// to call concurrently with other Resume/Yield/Status calls against
+// the same underlying global state (including from other coroutines
+// created from it, or via Chan) — they simply serialize.
+//
+// 5.4's lua_resume takes an extra "from" thread (nil here, since the
+// caller is Go, not a Lua thread) and returns the number of results
+// through an out-parameter instead of as part of its return value; see
+// coroutine_51.go for the 2-argument signature this replaces.
+func (c *Coroutine) Resume(args ...interface{}) ([]interface{}, int, error) {
+	c.s.vm.Lock()
+	defer c.s.vm.Unlock()
+
+	base := c.s.Gettop()
+	for _, a := range args {
+		if err := c.s.Push(a); err != nil {
+			return nil, Errrun, err
+		}
+	}
+	var nres C.int
+	status := int(C.lua_resume(c.s.l, nil, C.int(len(args)), &nres))
+	return c.resumeResults(base, status)
+}
+
+//export goContinuation
+func goContinuation(l *C.lua_State, status C.int, ctx C.lua_KContext) C.int {
+	contMu.Lock()
+	k, ok := continuations[uintptr(ctx)]
+	delete(continuations, uintptr(ctx))
+	contMu.Unlock()
+	if !ok {
+		return 0
+	}
+	return C.int(k(&State{l: l}, int(status)))
+}
@@ -0,0 +1,38 @@
+//go:build lua54
+
+package lua
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestNextContextConcurrentAccess is a regression test for the
+// continuations/lastContext race fixed by contMu: nextContext used to
+// increment lastContext with no lock at all, which -race would catch
+// the moment two goroutines called Callk/Pcallk against coroutines of
+// the same global state concurrently.
+func TestNextContextConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	seen := make(chan uintptr, 200)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen <- nextContext()
+		}()
+	}
+	wg.Wait()
+	close(seen)
+
+	ids := map[uintptr]bool{}
+	for id := range seen {
+		if ids[id] {
+			t.Fatalf("nextContext returned duplicate id %d", id)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 200 {
+		t.Fatalf("got %d unique ids, want 200", len(ids))
+	}
+}
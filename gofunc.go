@@ -0,0 +1,55 @@
+package lua
+
+/*
+#include <lua.h>
+
+extern int goCallTrampoline(lua_State *L);
+*/
+import "C"
+import "sync"
+
+// Gofunction is the signature of a Go function registered as a Lua C
+// function: it receives the State for the call it was invoked from
+// and returns the number of values it left on top of the stack.
+type Gofunction func(*State) int
+
+// funcs maps the id stored as a registered closure's sole upvalue to
+// the Gofunction it should invoke. Lua can only carry the id across
+// the cgo boundary as a plain integer, not a Go closure value, since
+// Go's garbage collector is free to move or collect anything it has
+// no visible reference to.
+var (
+	funcMu  sync.Mutex
+	funcs   = map[int]Gofunction{}
+	nextFID int
+)
+
+// Pushgofunction pushes fn as a Lua C function. Every function pushed
+// this way is wrapped with SafeCall first, so a fn that panics fails
+// the Lua call that invoked it instead of unwinding across the cgo
+// boundary into Lua's own setjmp/longjmp.
+func (s *State) Pushgofunction(fn Gofunction) {
+	safe := SafeCall(fn)
+
+	funcMu.Lock()
+	nextFID++
+	id := nextFID
+	funcs[id] = safe
+	funcMu.Unlock()
+
+	C.lua_pushinteger(s.l, C.lua_Integer(id))
+	C.lua_pushcclosure(s.l, (C.lua_CFunction)(C.goCallTrampoline), 1)
+}
+
+//export goCallTrampoline
+func goCallTrampoline(l *C.lua_State) C.int {
+	id := int(C.lua_tointeger(l, C.int(Upvalueindex(1))))
+
+	funcMu.Lock()
+	fn := funcs[id]
+	funcMu.Unlock()
+	if fn == nil {
+		return 0
+	}
+	return C.int(fn(&State{l: l}))
+}
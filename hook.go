@@ -0,0 +1,47 @@
+package lua
+
+/*
+#include <lua.h>
+
+extern void goHookTrampoline(lua_State *L, lua_Debug *ar);
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// Hookfunc is the signature of a Go debug hook installed with Sethook.
+type Hookfunc func(*State)
+
+// hooks maps a *C.lua_State, identified by its address, to the Go hook
+// installed for it, keyed the same way as the warners map in warn.go
+// and for the same reason: the hook
+// fires on a C call stack with no Go closure to carry along, only the
+// lua_State pointer it was invoked with.
+var (
+	hookMu sync.Mutex
+	hooks  = map[uintptr]Hookfunc{}
+)
+
+// Sethook installs fn as the state's debug hook, to be called
+// according to mask (Maskcall, Maskret, Maskline, Maskcount) and,
+// when Maskcount is set, every count instructions.
+func (s *State) Sethook(fn Hookfunc, mask, count int) {
+	key := uintptr(unsafe.Pointer(s.l))
+	hookMu.Lock()
+	hooks[key] = fn
+	hookMu.Unlock()
+	C.lua_sethook(s.l, (C.lua_Hook)(unsafe.Pointer(C.goHookTrampoline)), C.int(mask), C.int(count))
+}
+
+//export goHookTrampoline
+func goHookTrampoline(l *C.lua_State, ar *C.lua_Debug) {
+	key := uintptr(unsafe.Pointer(l))
+	hookMu.Lock()
+	fn := hooks[key]
+	hookMu.Unlock()
+	if fn != nil {
+		fn(&State{l: l})
+	}
+}
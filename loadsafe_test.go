@@ -0,0 +1,35 @@
+package lua
+
+import "testing"
+
+func TestCheckLoadMode(t *testing.T) {
+	binary := append([]byte(Signature), "garbage bytecode"...)
+	text := []byte("return 1")
+
+	cases := []struct {
+		name    string
+		src     []byte
+		mode    string
+		wantErr bool
+	}{
+		{"text with mode t", text, "t", false},
+		{"text with mode b", text, "b", true},
+		{"text with mode bt", text, "bt", false},
+		{"text with default mode", text, "", false},
+		{"binary with mode b", binary, "b", false},
+		{"binary with mode t", binary, "t", true},
+		{"binary with mode bt", binary, "bt", false},
+		{"empty source with mode t", nil, "t", false},
+		{"empty source with mode b", nil, "b", true},
+		{"invalid mode", text, "x", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkLoadMode(c.src, c.mode)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("checkLoadMode(%q, %q) = %v, want error: %v", c.src, c.mode, err, c.wantErr)
+			}
+		})
+	}
+}
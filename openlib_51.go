@@ -0,0 +1,49 @@
+//go:build !lua54
+
+package lua
+
+/*
+#include <lua.h>
+#include <lualib.h>
+*/
+import "C"
+import "fmt"
+
+// openBase opens the base library, which under 5.1 also provides the
+// coroutine.* functions (they were only split into their own library
+// in 5.2). luaopen_base self-registers its globals via luaL_register
+// and leaves its module table on the stack, so it is popped back off.
+func (s *State) openBase() {
+	C.luaopen_base(s.l)
+	s.Pop(1)
+}
+
+// openLib opens a single named standard library. Under 5.1 every
+// luaopen_* function already calls luaL_register and registers itself
+// as a global, and leaves its module table on the stack; this only
+// needs to pop that table back off afterward.
+func (s *State) openLib(name string) error {
+	switch name {
+	case Tablibname:
+		C.luaopen_table(s.l)
+	case Strlibname:
+		C.luaopen_string(s.l)
+	case Mathlibname:
+		C.luaopen_math(s.l)
+	case IOlibname:
+		C.luaopen_io(s.l)
+	case OSlibname:
+		C.luaopen_os(s.l)
+	case Dblibname:
+		C.luaopen_debug(s.l)
+	case Loadlibname:
+		C.luaopen_package(s.l)
+	case Colibname:
+		// coroutine.* lives in base under 5.1; nothing left to open.
+		return nil
+	default:
+		return fmt.Errorf("lua: unknown sandbox library %q", name)
+	}
+	s.Pop(1)
+	return nil
+}
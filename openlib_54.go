@@ -0,0 +1,58 @@
+//go:build lua54
+
+package lua
+
+/*
+#include <lua.h>
+#include <lualib.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// openBase opens the base library under its "_G" name. 5.2+ dropped
+// the self-registering luaopen_base/luaL_register pairing in favor of
+// luaL_requiref, which both calls the opener and binds its result to
+// the given global name, leaving the module table on the stack.
+func (s *State) openBase() {
+	require(s.l, "_G", (C.lua_CFunction)(C.luaopen_base))
+	s.Pop(1)
+}
+
+// openLib opens a single named standard library with luaL_requiref,
+// which 5.2+ uses in place of the self-registering luaopen_* of 5.1.
+func (s *State) openLib(name string) error {
+	var opener C.lua_CFunction
+	switch name {
+	case Tablibname:
+		opener = (C.lua_CFunction)(C.luaopen_table)
+	case Strlibname:
+		opener = (C.lua_CFunction)(C.luaopen_string)
+	case Mathlibname:
+		opener = (C.lua_CFunction)(C.luaopen_math)
+	case IOlibname:
+		opener = (C.lua_CFunction)(C.luaopen_io)
+	case OSlibname:
+		opener = (C.lua_CFunction)(C.luaopen_os)
+	case Dblibname:
+		opener = (C.lua_CFunction)(C.luaopen_debug)
+	case Loadlibname:
+		opener = (C.lua_CFunction)(C.luaopen_package)
+	case Colibname:
+		opener = (C.lua_CFunction)(C.luaopen_coroutine)
+	default:
+		return fmt.Errorf("lua: unknown sandbox library %q", name)
+	}
+	require(s.l, name, opener)
+	s.Pop(1)
+	return nil
+}
+
+func require(l *C.lua_State, name string, opener C.lua_CFunction) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.luaL_requiref(l, cname, opener, 1)
+}
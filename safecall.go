@@ -0,0 +1,80 @@
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+import "fmt"
+
+// SafeCall wraps fn so a Go panic raised while it runs is recovered and
+// turned into a clean lua_error call instead of unwinding across the
+// cgo boundary into Lua's own setjmp/longjmp, which has no idea a Go
+// panic is in flight and will corrupt the C stack. Pushgofunction
+// applies SafeCall to every Go function it registers, so a callback
+// that panics fails the Lua call that invoked it rather than crashing
+// the process.
+func SafeCall(fn Gofunction) Gofunction {
+	return func(s *State) (n int) {
+		defer func() {
+			if r := recover(); r != nil {
+				// lua_error itself longjmps, but only after this defer
+				// chain (and any the panicking frame set up) has fully
+				// unwound, which is what makes this safe.
+				n = s.Error(fmt.Sprintf("panic in Go function: %v", r))
+			}
+		}()
+		return fn(s)
+	}
+}
+
+// Pcall is the Go-side equivalent of lua_pcall. It installs Lua's own
+// debug.traceback as the message handler, so a failing call deep under
+// Go code still carries the Lua stack trace back up, and turns the
+// Errrun/Errmem/Errerr codes already enumerated in const.go into a Go
+// error instead of leaving the caller to check return codes by hand.
+//
+// Pcall takes s's vm lock for its duration, the same as Coroutine's
+// Resume and State's Callk/Pcallk: the call it makes can run arbitrary Lua code,
+// including code that resumes a coroutine sharing this same vm, so it
+// is one of the entry points vm serializes rather than a plain stack
+// accessor.
+func (s *State) Pcall(nargs, nresults int) error {
+	s.vm.Lock()
+	defer s.vm.Unlock()
+
+	base := s.Gettop() - nargs
+
+	// Only reach for debug.traceback if the debug library is actually
+	// loaded: indexing a non-table "debug" global with Getfield would
+	// itself raise a Lua error, and we are not inside a protected call
+	// yet to catch it.
+	s.Getglobal("debug")
+	haveTraceback := false
+	if s.Type(-1) == Ttable {
+		s.Getfield(-1, "traceback")
+		haveTraceback = s.Type(-1) == Tfunction
+		s.Remove(-2) // drop the debug table, keep whatever traceback resolved to
+	}
+
+	msgh := 0
+	if haveTraceback {
+		msgh = base
+		s.Insert(msgh)
+	} else {
+		s.Pop(1) // drop the non-table debug global, or the non-function traceback field
+	}
+
+	rc := int(C.lua_pcall(s.l, C.int(nargs), C.int(nresults), C.int(msgh)))
+	if haveTraceback {
+		s.Remove(msgh)
+	}
+	if rc == Ok {
+		return nil
+	}
+	msg := s.Tostring(-1)
+	s.Pop(1)
+	if err := numtoerror(rc); err != nil {
+		return fmt.Errorf("%s: %s", err, msg)
+	}
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,44 @@
+package lua
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeCallRecoversPanic(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	s.Pushgofunction(func(s *State) int {
+		panic("boom")
+	})
+	s.Setglobal("boom")
+
+	s.Getglobal("boom")
+	err := s.Pcall(0, 0)
+	if err == nil {
+		t.Fatal("expected the panicking Go function to fail the call instead of crashing")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the panic value in the error, got %v", err)
+	}
+}
+
+func TestSafeCallPassesThroughNormalReturn(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	s.Pushgofunction(func(s *State) int {
+		s.Pushnumber(42)
+		return 1
+	})
+	s.Setglobal("fortytwo")
+
+	s.Getglobal("fortytwo")
+	if err := s.Pcall(0, 1); err != nil {
+		t.Fatalf("Pcall: %v", err)
+	}
+	if got := s.Tonumber(-1); got != 42 {
+		t.Fatalf("got %v, want 42", got)
+	}
+}
@@ -0,0 +1,149 @@
+package lua
+
+/*
+#include <lua.h>
+#include <stdlib.h>
+
+extern void *goSandboxAlloc(void *ud, void *ptr, size_t osize, size_t nsize);
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// SandboxOptions configures the subset of the standard library
+// NewSandbox opens and the resource limits it enforces.
+type SandboxOptions struct {
+	// Libs names the lualib constants to open (Tablibname, Strlibname,
+	// Mathlibname, ...). The base library is always opened, since a
+	// state with no pcall/type/tostring is unusable from Lua code.
+	Libs []string
+	// MaxInstructions caps the number of VM instructions a single call
+	// may run for, enforced with Sethook/Maskcount. Zero disables it.
+	MaxInstructions int
+	// MaxBytes caps the memory the state's custom allocator will hand
+	// out, in bytes. Zero disables it.
+	MaxBytes int
+}
+
+// ErrInstructionLimit's message is raised as a Lua error once a
+// sandboxed call has run for more than MaxInstructions VM instructions.
+// Pcall reports it as text inside its own error, not as this value.
+var ErrInstructionLimit = fmt.Errorf("lua: sandbox instruction limit reached")
+
+// sandboxAllocator is the Go side of the custom lua_Alloc a sandboxed
+// state is created with: it tracks bytes currently handed out and
+// refuses to grow past max, mirroring what lua_newstate's default
+// allocator would do if malloc itself failed.
+type sandboxAllocator struct {
+	mu   sync.Mutex
+	used C.size_t
+	max  C.size_t
+}
+
+// allocs maps the key handed to lua_newstate as its userdata to the
+// sandboxAllocator it should charge, since a Go pointer to the
+// allocator can't itself cross the cgo boundary as void* once Go's GC
+// is free to move it.
+var (
+	allocMu sync.Mutex
+	allocs  = map[uintptr]*sandboxAllocator{}
+	lastKey uintptr
+)
+
+// NewSandbox creates a fresh Lua state with only the libraries named in
+// opts.Libs opened, a Hookcount-based instruction ceiling, and a memory
+// ceiling enforced by a custom lua_Alloc. The returned State owns its
+// own lua_State (lua_newstate, not lua_open/luaL_newstate off a shared
+// one), so it is closed and its allocator entry released automatically
+// once it is garbage collected.
+func NewSandbox(opts SandboxOptions) (*State, error) {
+	alloc := &sandboxAllocator{max: C.size_t(opts.MaxBytes)}
+	allocMu.Lock()
+	lastKey++
+	key := lastKey
+	allocs[key] = alloc
+	allocMu.Unlock()
+
+	release := func() {
+		allocMu.Lock()
+		delete(allocs, key)
+		allocMu.Unlock()
+	}
+
+	ud := unsafe.Pointer(uintptr(key))
+	l := C.lua_newstate((C.lua_Alloc)(unsafe.Pointer(C.goSandboxAlloc)), ud)
+	if l == nil {
+		release()
+		return nil, fmt.Errorf("lua: lua_newstate failed")
+	}
+	s := &State{l: l, vm: new(sync.Mutex)}
+	runtime.SetFinalizer(s, func(s *State) {
+		release()
+		s.Close()
+	})
+
+	s.openBase()
+	for _, lib := range opts.Libs {
+		if err := s.openLib(lib); err != nil {
+			runtime.SetFinalizer(s, nil)
+			release()
+			C.lua_close(l)
+			return nil, err
+		}
+	}
+
+	if opts.MaxInstructions > 0 {
+		s.Sethook(sandboxCountHook, Maskcount, opts.MaxInstructions)
+	}
+	return s, nil
+}
+
+// sandboxCountHook is installed as the Hookcount handler for every
+// sandboxed state. It calls Error directly rather than panicking: a
+// debug hook runs on the Lua call stack the same as any other C
+// function, and raising lua_error from inside one to unwind a
+// runaway script is the documented way to do it, not a case SafeCall
+// needs to guard.
+func sandboxCountHook(s *State) {
+	s.Error(ErrInstructionLimit.Error())
+}
+
+//export goSandboxAlloc
+func goSandboxAlloc(ud, ptr unsafe.Pointer, osize, nsize C.size_t) unsafe.Pointer {
+	allocMu.Lock()
+	alloc := allocs[uintptr(ud)]
+	allocMu.Unlock()
+	if alloc == nil {
+		return defaultRealloc(ptr, osize, nsize)
+	}
+
+	alloc.mu.Lock()
+	defer alloc.mu.Unlock()
+
+	if nsize == 0 {
+		C.free(ptr)
+		alloc.used -= osize
+		return nil
+	}
+	if alloc.max > 0 && alloc.used-osize+nsize > alloc.max {
+		return nil
+	}
+	p := C.realloc(ptr, nsize)
+	if p == nil {
+		return nil
+	}
+	alloc.used = alloc.used - osize + nsize
+	return p
+}
+
+func defaultRealloc(ptr unsafe.Pointer, osize, nsize C.size_t) unsafe.Pointer {
+	if nsize == 0 {
+		C.free(ptr)
+		return nil
+	}
+	return C.realloc(ptr, nsize)
+}
@@ -0,0 +1,55 @@
+package lua
+
+import "testing"
+
+func TestNewSandboxUnknownLibrary(t *testing.T) {
+	_, err := NewSandbox(SandboxOptions{Libs: []string{"nonexistent"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown sandbox library")
+	}
+}
+
+func TestNewSandboxInstructionLimit(t *testing.T) {
+	s, err := NewSandbox(SandboxOptions{MaxInstructions: 1000})
+	if err != nil {
+		t.Fatalf("NewSandbox: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.LoadSafe([]byte("while true do end"), "infinite-loop", "t"); err != nil {
+		t.Fatalf("LoadSafe: %v", err)
+	}
+	if err := s.Pcall(0, 0); err == nil {
+		t.Fatal("expected the instruction ceiling to interrupt an infinite loop")
+	}
+}
+
+func TestNewSandboxMemoryLimit(t *testing.T) {
+	// 64 KiB is enough for lua_newstate's own bookkeeping allocations to
+	// succeed (a handful of small structs, nowhere near this budget),
+	// but nowhere near enough for the 100000-entry table below, so the
+	// ceiling is hit inside Pcall rather than during NewSandbox itself.
+	const maxBytes = 64 * 1024
+	s, err := NewSandbox(SandboxOptions{MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatalf("NewSandbox: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.LoadSafe([]byte("local t = {} for i = 1, 100000 do t[i] = i end"), "big-table", "t"); err != nil {
+		t.Fatalf("LoadSafe: %v", err)
+	}
+	if err := s.Pcall(0, 0); err == nil {
+		t.Fatal("expected the tiny memory ceiling to fail the chunk during Pcall")
+	}
+}
+
+func TestLoadSafeRejectsBinaryInTextMode(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	binary := append([]byte(Signature), "not a real chunk"...)
+	if err := s.LoadSafe(binary, "fake-bytecode", "t"); err == nil {
+		t.Fatal("expected LoadSafe to reject a binary-looking chunk in text-only mode")
+	}
+}
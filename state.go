@@ -0,0 +1,156 @@
+package lua
+
+/*
+#include <lua.h>
+#include <lauxlib.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// State wraps a *C.lua_State behind the Go-idiomatic method set the
+// rest of this package, its subpackages (bind, ...), and every method
+// added across this series are built on.
+//
+// vm is shared between a State and any Coroutine (see coroutine.go)
+// created from it: Lua's C API is not safe to enter from two Go
+// goroutines at once even for two different threads of the same
+// global state. vm is only taken by the entry points that actually run
+// Lua code and can therefore call back into it arbitrarily deep —
+// Pcall, Callk/Pcallk, and Coroutine's Resume/Status — not by the plain
+// stack accessors (Gettop, Pushvalue, Getfield, ...), which are meant
+// to be composed inside one of those already-locked calls. A goroutine
+// driving a Coroutine via Resume/Chan must not also drive the parent
+// State's stack directly from another goroutine without its own
+// serialization; only calls through the locked entry points are safe
+// to interleave.
+type State struct {
+	l  *C.lua_State
+	vm *sync.Mutex
+}
+
+// NewState creates a fresh Lua state with no libraries opened, the Go
+// equivalent of luaL_newstate.
+func NewState() *State {
+	return &State{l: C.luaL_newstate(), vm: new(sync.Mutex)}
+}
+
+// Close releases the state's resources. Safe to call more than once.
+func (s *State) Close() {
+	if s.l != nil {
+		C.lua_close(s.l)
+		s.l = nil
+	}
+}
+
+// --- stack manipulation ---
+
+func (s *State) Gettop() int    { return int(C.lua_gettop(s.l)) }
+func (s *State) SetTop(idx int) { C.lua_settop(s.l, C.int(idx)) }
+func (s *State) Pop(n int)      { s.SetTop(-n - 1) }
+
+func (s *State) Pushvalue(idx int) { C.lua_pushvalue(s.l, C.int(idx)) }
+func (s *State) Insert(idx int)    { C.lua_insert(s.l, C.int(idx)) }
+func (s *State) Remove(idx int)    { C.lua_remove(s.l, C.int(idx)) }
+
+// --- pushing values ---
+
+func (s *State) Pushnil()             { C.lua_pushnil(s.l) }
+func (s *State) Pushnumber(n float64) { C.lua_pushnumber(s.l, C.lua_Number(n)) }
+
+func (s *State) Pushboolean(b bool) {
+	var i C.int
+	if b {
+		i = 1
+	}
+	C.lua_pushboolean(s.l, i)
+}
+
+func (s *State) Pushstring(str string) {
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+	C.lua_pushlstring(s.l, cstr, C.size_t(len(str)))
+}
+
+// --- tables ---
+
+func (s *State) Newtable() { C.lua_createtable(s.l, 0, 0) }
+
+func (s *State) Setfield(idx int, name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.lua_setfield(s.l, C.int(idx), cname)
+}
+
+func (s *State) Getfield(idx int, name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.lua_getfield(s.l, C.int(idx), cname)
+}
+
+func (s *State) Rawset(idx int)     { C.lua_rawset(s.l, C.int(idx)) }
+func (s *State) Rawget(idx int)     { C.lua_rawget(s.l, C.int(idx)) }
+func (s *State) Rawseti(idx, n int) { C.lua_rawseti(s.l, C.int(idx), C.int(n)) }
+func (s *State) Rawgeti(idx, n int) { C.lua_rawgeti(s.l, C.int(idx), C.int(n)) }
+
+// Next pushes the next key/value pair after the one at the top of the
+// stack (lua_next semantics) and reports whether there was one.
+func (s *State) Next(idx int) bool {
+	return C.lua_next(s.l, C.int(idx)) != 0
+}
+
+// --- userdata / metatables ---
+
+func (s *State) Newuserdata(size uintptr) unsafe.Pointer {
+	return unsafe.Pointer(C.lua_newuserdata(s.l, C.size_t(size)))
+}
+
+// Newmetatable is luaL_newmetatable: it pushes the named metatable,
+// creating it (and returning true) the first time name is used.
+func (s *State) Newmetatable(name string) bool {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return C.luaL_newmetatable(s.l, cname) != 0
+}
+
+func (s *State) Setmetatable(idx int) { C.lua_setmetatable(s.l, C.int(idx)) }
+
+func (s *State) Touserdata(idx int) unsafe.Pointer {
+	return unsafe.Pointer(C.lua_touserdata(s.l, C.int(idx)))
+}
+
+// --- type queries / conversions ---
+
+func (s *State) Type(idx int) int { return int(C.lua_type(s.l, C.int(idx))) }
+
+func (s *State) Tostring(idx int) string {
+	var length C.size_t
+	cstr := C.lua_tolstring(s.l, C.int(idx), &length)
+	if cstr == nil {
+		return ""
+	}
+	return C.GoStringN(cstr, C.int(length))
+}
+
+func (s *State) Toboolean(idx int) bool {
+	return C.lua_toboolean(s.l, C.int(idx)) != 0
+}
+
+func (s *State) Tonumber(idx int) float64 {
+	return float64(C.lua_tonumber(s.l, C.int(idx)))
+}
+
+// --- errors ---
+
+// Error raises msg as a Lua error. Like lua_error itself it never
+// returns to its caller (it longjmps to the nearest protected call);
+// the int result just lets it be used as the tail expression of a
+// Gofunction, e.g. `return s.Error("bad argument")`.
+func (s *State) Error(msg string) int {
+	s.Pushstring(msg)
+	C.lua_error(s.l)
+	return 0
+}
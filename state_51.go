@@ -0,0 +1,26 @@
+//go:build !lua54
+
+package lua
+
+/*
+#include <lua.h>
+*/
+import "C"
+
+// Getglobal pushes the value of global name. 5.1 has no lua_getglobal
+// function; it's a macro for lua_getfield at Globalsindex.
+func (s *State) Getglobal(name string) {
+	s.Getfield(Globalsindex, name)
+}
+
+// Setglobal pops the value on top of the stack into global name.
+func (s *State) Setglobal(name string) {
+	s.Setfield(Globalsindex, name)
+}
+
+// Objlen reports the "length" of the value at idx, the same length
+// the # operator would report; named after 5.1's lua_objlen, renamed
+// lua_rawlen in 5.4.
+func (s *State) Objlen(idx int) int {
+	return int(C.lua_objlen(s.l, C.int(idx)))
+}
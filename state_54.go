@@ -0,0 +1,33 @@
+//go:build lua54
+
+package lua
+
+/*
+#include <lua.h>
+#include <stdlib.h>
+*/
+import "C"
+import "unsafe"
+
+// Getglobal pushes the value of global name, via the real lua_getglobal
+// 5.2+ added (5.1 only has it as a lua_getfield/Globalsindex macro; see
+// state_51.go).
+func (s *State) Getglobal(name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.lua_getglobal(s.l, cname)
+}
+
+// Setglobal pops the value on top of the stack into global name.
+func (s *State) Setglobal(name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.lua_setglobal(s.l, cname)
+}
+
+// Objlen reports the "length" of the value at idx, the same length
+// the # operator would report; lua_objlen was renamed lua_rawlen in
+// 5.4, see state_51.go for the name it replaces.
+func (s *State) Objlen(idx int) int {
+	return int(C.lua_rawlen(s.l, C.int(idx)))
+}
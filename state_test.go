@@ -0,0 +1,99 @@
+package lua
+
+import "testing"
+
+func TestStateStackPrimitives(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	s.Pushboolean(true)
+	s.Pushnumber(3.5)
+	s.Pushstring("hi")
+
+	if got := s.Gettop(); got != 3 {
+		t.Fatalf("Gettop() = %d, want 3", got)
+	}
+	if got := s.Type(-1); got != Tstring {
+		t.Fatalf("Type(-1) = %d, want Tstring", got)
+	}
+	if got := s.Tostring(-1); got != "hi" {
+		t.Fatalf("Tostring(-1) = %q, want %q", got, "hi")
+	}
+	if got := s.Tonumber(-2); got != 3.5 {
+		t.Fatalf("Tonumber(-2) = %v, want 3.5", got)
+	}
+	if got := s.Toboolean(-3); got != true {
+		t.Fatalf("Toboolean(-3) = %v, want true", got)
+	}
+
+	s.Pop(2)
+	if got := s.Gettop(); got != 1 {
+		t.Fatalf("Gettop() after Pop(2) = %d, want 1", got)
+	}
+}
+
+func TestPushgofunctionDispatch(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	s.Pushgofunction(func(s *State) int {
+		a := s.Tonumber(1)
+		b := s.Tonumber(2)
+		s.Pushnumber(a + b)
+		return 1
+	})
+	s.Setglobal("add")
+
+	s.Getglobal("add")
+	s.Pushnumber(2)
+	s.Pushnumber(3)
+	if err := s.Pcall(2, 1); err != nil {
+		t.Fatalf("Pcall: %v", err)
+	}
+	if got := s.Tonumber(-1); got != 5 {
+		t.Fatalf("got %v, want 5", got)
+	}
+}
+
+func TestSethookFiresOnCount(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	fired := false
+	s.Sethook(func(s *State) {
+		fired = true
+	}, Maskcount, 1)
+
+	// The count hook only fires while Lua bytecode runs, so call a Lua
+	// chunk rather than a Go function.
+	if err := s.LoadSafe([]byte("local x = 1"), "count-hook", "t"); err != nil {
+		t.Fatalf("LoadSafe: %v", err)
+	}
+	if err := s.Pcall(0, 0); err != nil {
+		t.Fatalf("Pcall: %v", err)
+	}
+	if !fired {
+		t.Fatal("expected the count hook to fire during the call")
+	}
+}
+
+func TestValuePushToValueRoundtrip(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	cases := []interface{}{nil, true, "hello", 42.0}
+	for _, c := range cases {
+		if err := s.Push(c); err != nil {
+			t.Fatalf("Push(%v): %v", c, err)
+		}
+		got := s.ToValue(-1)
+		s.Pop(1)
+		if got != c {
+			t.Fatalf("ToValue round-trip of %v (%T) = %v (%T)", c, c, got, got)
+		}
+	}
+
+	if err := s.Push(struct{}{}); err == nil {
+		t.Fatal("expected Push to reject an unsupported type")
+	}
+}
@@ -0,0 +1,49 @@
+package lua
+
+import "fmt"
+
+// Push pushes a Go value as the corresponding Lua value: bools,
+// strings, int, int64, float32 and float64 map directly and nil becomes
+// Lua nil. Anything else, other numeric types included, is rejected
+// rather than silently coerced.
+func (s *State) Push(v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		s.Pushnil()
+	case bool:
+		s.Pushboolean(val)
+	case string:
+		s.Pushstring(val)
+	case int:
+		s.Pushnumber(float64(val))
+	case int64:
+		s.Pushnumber(float64(val))
+	case float32:
+		s.Pushnumber(float64(val))
+	case float64:
+		s.Pushnumber(val)
+	default:
+		return fmt.Errorf("lua: Push: unsupported type %T", v)
+	}
+	return nil
+}
+
+// ToValue converts the Lua value at idx to the Go type closest to it:
+// booleans, numbers and strings come back as bool/float64/string, and
+// nil comes back as nil. Tables, functions, userdata and threads also
+// come back as nil, since there is no single Go type that represents
+// them without more context than ToValue has.
+func (s *State) ToValue(idx int) interface{} {
+	switch s.Type(idx) {
+	case Tnil, Tnone:
+		return nil
+	case Tboolean:
+		return s.Toboolean(idx)
+	case Tnumber:
+		return s.Tonumber(idx)
+	case Tstring:
+		return s.Tostring(idx)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+package lua
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// WarnFunc is the signature of a Go-side warning handler installed with
+// Setwarnf. msg is the fully reassembled warning message; tocont reports
+// whether the underlying C API considers the warning still open (this is
+// only ever true for handlers that call back into Lua mid-warning, which
+// Setwarnf does not expose).
+type WarnFunc func(msg string, tocont bool)
+
+// warnMu guards warners: the warning trampoline can legitimately fire
+// from a C call stack the Go runtime is free to schedule on any OS
+// thread, concurrently with a Setwarnf call installing a handler for a
+// different state, so plain map access isn't safe here (same pattern
+// as allocMu in sandbox.go).
+var (
+	warnMu  sync.Mutex
+	warners = map[uintptr]*warnState{}
+)
+
+// warnState reassembles the possibly-multi-part warning the C API
+// delivers fragment by fragment into a single call to the user's
+// WarnFunc once the final fragment (tocont == false) arrives.
+type warnState struct {
+	fn  WarnFunc
+	buf []byte
+}
+
+func warnerKey(l unsafe.Pointer) uintptr {
+	return uintptr(l)
+}
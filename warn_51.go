@@ -0,0 +1,46 @@
+//go:build !lua54
+
+package lua
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+// Setwarnf installs fn as the state's warning handler. Lua 5.1 has no
+// lua_setwarnf of its own; this only remembers fn for Warning to call,
+// so code written against Setwarnf/Warning is portable between build
+// tags even though 5.1 never calls it on its own.
+func (s *State) Setwarnf(fn WarnFunc) {
+	key := warnerKey(unsafe.Pointer(s.l))
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	if fn == nil {
+		delete(warners, key)
+		return
+	}
+	warners[key] = &warnState{fn: fn}
+}
+
+// Warning reports msg through the installed handler, reassembling
+// fragments sent with tocont set into one call the same way the 5.4
+// trampoline does, and falling back to
+// printing to stderr (the same default behavior 5.4's C API has) when
+// none was installed, so the portability shim is never silent.
+func (s *State) Warning(msg string, tocont bool) {
+	warnMu.Lock()
+	w, ok := warners[warnerKey(unsafe.Pointer(s.l))]
+	warnMu.Unlock()
+	if ok {
+		w.buf = append(w.buf, msg...)
+		if tocont {
+			return
+		}
+		full := string(w.buf)
+		w.buf = w.buf[:0]
+		w.fn(full, false)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Lua warning: %s\n", msg)
+}
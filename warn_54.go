@@ -0,0 +1,58 @@
+//go:build lua54
+
+package lua
+
+/*
+#include <lua.h>
+#include <stdlib.h>
+
+extern void goWarnfTrampoline(void *ud, char *msg, int tocont);
+*/
+import "C"
+import "unsafe"
+
+// Setwarnf installs fn as the state's warning handler, analogous to
+// Sethook: the underlying lua_WarningFunction is a single cgo trampoline
+// shared by every state, dispatching by the *C.lua_State it was called
+// with.
+func (s *State) Setwarnf(fn WarnFunc) {
+	key := warnerKey(unsafe.Pointer(s.l))
+	warnMu.Lock()
+	if fn == nil {
+		delete(warners, key)
+		warnMu.Unlock()
+		return
+	}
+	warners[key] = &warnState{fn: fn}
+	warnMu.Unlock()
+	C.lua_setwarnf(s.l, (C.lua_WarningFunction)(unsafe.Pointer(C.goWarnfTrampoline)), unsafe.Pointer(s.l))
+}
+
+// Warning emits a warning from Go through the same channel as the C API,
+// so library code written in Go and in C/Lua share one diagnostic path.
+func (s *State) Warning(msg string, tocont bool) {
+	cmsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cmsg))
+	var tc C.int
+	if tocont {
+		tc = 1
+	}
+	C.lua_warning(s.l, cmsg, tc)
+}
+
+//export goWarnfTrampoline
+func goWarnfTrampoline(ud unsafe.Pointer, msg *C.char, tocont C.int) {
+	warnMu.Lock()
+	w, ok := warners[warnerKey(ud)]
+	warnMu.Unlock()
+	if !ok {
+		return
+	}
+	w.buf = append(w.buf, C.GoString(msg)...)
+	if tocont != 0 {
+		return
+	}
+	full := string(w.buf)
+	w.buf = w.buf[:0]
+	w.fn(full, false)
+}
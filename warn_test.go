@@ -0,0 +1,49 @@
+package lua
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetwarnfAndWarning(t *testing.T) {
+	s := NewState()
+	defer s.Close()
+
+	var got string
+	s.Setwarnf(func(msg string, tocont bool) {
+		got = msg
+	})
+	s.Warning("heads up", false)
+	if got != "heads up" {
+		t.Fatalf("got %q, want %q", got, "heads up")
+	}
+}
+
+// TestWarnersMapConcurrentAccess is a regression test for the warners
+// map race fixed by warnMu: Setwarnf and Warning on independent states
+// used to read and write the shared warners map with no lock at all,
+// which -race would catch as soon as two states' handlers were set up
+// concurrently.
+func TestWarnersMapConcurrentAccess(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := NewState()
+			defer s.Close()
+
+			var mu sync.Mutex
+			var msgs []string
+			s.Setwarnf(func(msg string, tocont bool) {
+				mu.Lock()
+				msgs = append(msgs, msg)
+				mu.Unlock()
+			})
+			for j := 0; j < 50; j++ {
+				s.Warning("tick", false)
+			}
+		}()
+	}
+	wg.Wait()
+}